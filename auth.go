@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// User is an API-key holder. Only KeyHash is ever persisted; the plaintext
+// key is handed to the caller once, at mint time, and never stored.
+type User struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	KeyHash   string    `json:"key_hash"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// IsAdmin is true only for the bootstrap admin created from ADMIN_TOKEN.
+	// It gates admin-only endpoints such as POST /api/auth/tokens, so an
+	// ordinary API key can't mint further keys for itself.
+	IsAdmin bool `json:"is_admin,omitempty"`
+}
+
+// UserStore persists Users to a JSON file using the same load-whole/save-whole
+// approach as Store, since the expected number of API keys is small.
+type UserStore struct {
+	mu       sync.Mutex
+	users    []User
+	filepath string
+}
+
+func NewUserStore(filepath string) *UserStore {
+	us := &UserStore{filepath: filepath}
+	us.load()
+	return us
+}
+
+func (us *UserStore) load() {
+	data, err := os.ReadFile(us.filepath)
+	if err != nil {
+		return
+	}
+	json.Unmarshal(data, &us.users)
+}
+
+func (us *UserStore) save() error {
+	data, err := json.MarshalIndent(us.users, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(us.filepath, data, 0644)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// Create mints a new API key for name and returns the user record alongside
+// the plaintext key. The key is recoverable only from this return value.
+func (us *UserStore) Create(name string) (User, string, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return User{}, "", err
+	}
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	u := User{
+		ID:        hashAPIKey(key)[:12],
+		Name:      name,
+		KeyHash:   hashAPIKey(key),
+		CreatedAt: time.Now(),
+	}
+	us.users = append(us.users, u)
+	if err := us.save(); err != nil {
+		return User{}, "", err
+	}
+	return u, key, nil
+}
+
+// Authenticate looks up the user owning key using a constant-time comparison
+// of key hashes, so response timing doesn't leak which prefix matched.
+func (us *UserStore) Authenticate(key string) (User, bool) {
+	hash := hashAPIKey(key)
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	for _, u := range us.users {
+		if subtle.ConstantTimeCompare([]byte(u.KeyHash), []byte(hash)) == 1 {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+// bootstrapAdmin ensures a user authenticating with token exists, so an
+// operator can set ADMIN_TOKEN and immediately call the API without first
+// minting a key through it. It's a no-op once that token has been seen.
+func (us *UserStore) bootstrapAdmin(token string) error {
+	if token == "" {
+		return nil
+	}
+	if _, ok := us.Authenticate(token); ok {
+		return nil
+	}
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	u := User{
+		ID:        "admin",
+		Name:      "admin",
+		KeyHash:   hashAPIKey(token),
+		CreatedAt: time.Now(),
+		IsAdmin:   true,
+	}
+	us.users = append(us.users, u)
+	return us.save()
+}
+
+// RateLimiter hands out a token-bucket limiter per API key, so one caller
+// hammering the API can't starve the others.
+type RateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	rps      rate.Limit
+	burst    int
+}
+
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limiters: make(map[string]*rate.Limiter),
+		rps:      rate.Limit(rps),
+		burst:    burst,
+	}
+}
+
+func (rl *RateLimiter) Allow(userID string) bool {
+	rl.mu.Lock()
+	limiter, ok := rl.limiters[userID]
+	if !ok {
+		limiter = rate.NewLimiter(rl.rps, rl.burst)
+		rl.limiters[userID] = limiter
+	}
+	rl.mu.Unlock()
+	return limiter.Allow()
+}
+
+type userContextKey struct{}
+
+func userFromContext(r *http.Request) (User, bool) {
+	u, ok := r.Context().Value(userContextKey{}).(User)
+	return u, ok
+}
+
+var errMissingBearer = errors.New("missing bearer token")
+
+func bearerToken(r *http.Request) (string, error) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(auth, prefix) {
+		return "", errMissingBearer
+	}
+	return strings.TrimPrefix(auth, prefix), nil
+}
+
+// requireAuth validates the Authorization: Bearer <key> header against
+// users, enforces a per-key rate limit, and injects the resolved User into
+// the request context before calling next.
+func requireAuth(users *UserStore, limiter *RateLimiter, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		key, err := bearerToken(r)
+		if err != nil {
+			http.Error(w, `{"error":"missing or malformed Authorization header"}`, http.StatusUnauthorized)
+			return
+		}
+		user, ok := users.Authenticate(key)
+		if !ok {
+			http.Error(w, `{"error":"invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		if !limiter.Allow(user.ID) {
+			http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
+			return
+		}
+		ctx := context.WithValue(r.Context(), userContextKey{}, user)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// requireAdmin wraps a requireAuth-protected handler with an additional
+// check that the resolved user is the bootstrap admin, for endpoints like
+// minting new API keys that ordinary users shouldn't be able to reach.
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _ := userFromContext(r)
+		if !user.IsAdmin {
+			http.Error(w, `{"error":"admin API key required"}`, http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
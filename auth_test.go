@@ -0,0 +1,83 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestUserStore(t *testing.T) *UserStore {
+	t.Helper()
+	return NewUserStore(filepath.Join(t.TempDir(), "users.json"))
+}
+
+func TestUserStoreAuthenticate(t *testing.T) {
+	us := newTestUserStore(t)
+	u, key, err := us.Create("alice")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if u.IsAdmin {
+		t.Error("a minted user should not be an admin")
+	}
+
+	got, ok := us.Authenticate(key)
+	if !ok || got.ID != u.ID {
+		t.Errorf("Authenticate(valid key) = (%+v, %v), want (%+v, true)", got, ok, u)
+	}
+
+	if _, ok := us.Authenticate("not-a-real-key"); ok {
+		t.Error("Authenticate should reject an unknown key")
+	}
+}
+
+func TestBootstrapAdminIsAdmin(t *testing.T) {
+	us := newTestUserStore(t)
+	if err := us.bootstrapAdmin("boot-token"); err != nil {
+		t.Fatalf("bootstrapAdmin: %v", err)
+	}
+
+	u, ok := us.Authenticate("boot-token")
+	if !ok {
+		t.Fatal("expected the bootstrap token to authenticate")
+	}
+	if !u.IsAdmin {
+		t.Error("the bootstrap admin user should have IsAdmin set")
+	}
+
+	// A regular minted user must not pick up admin rights.
+	u2, key2, err := us.Create("bob")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if u2.IsAdmin {
+		t.Error("a minted user should never be an admin")
+	}
+	got2, _ := us.Authenticate(key2)
+	if got2.IsAdmin {
+		t.Error("Authenticate should not report a minted user as admin")
+	}
+}
+
+func TestBootstrapAdminEmptyTokenIsNoop(t *testing.T) {
+	us := newTestUserStore(t)
+	if err := us.bootstrapAdmin(""); err != nil {
+		t.Fatalf("bootstrapAdmin(\"\"): %v", err)
+	}
+	if len(us.users) != 0 {
+		t.Errorf("expected no users to be created for an empty token, got %d", len(us.users))
+	}
+}
+
+func TestRateLimiterPerKey(t *testing.T) {
+	rl := NewRateLimiter(1, 1)
+	if !rl.Allow("alice") {
+		t.Error("first request for alice should be allowed")
+	}
+	if rl.Allow("alice") {
+		t.Error("second immediate request for alice should be rate-limited")
+	}
+	// bob has his own bucket, unaffected by alice's.
+	if !rl.Allow("bob") {
+		t.Error("bob's first request should be allowed independently of alice's bucket")
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// parseTodoFilter builds a TodoFilter from the query parameters accepted by
+// GET /api/todos: completed, limit, offset, sort and q.
+func parseTodoFilter(q url.Values) (TodoFilter, error) {
+	filter := TodoFilter{Search: q.Get("q"), Sort: q.Get("sort")}
+
+	if v := q.Get("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return TodoFilter{}, fmt.Errorf("invalid completed value %q", v)
+		}
+		filter.Completed = &completed
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return TodoFilter{}, fmt.Errorf("invalid limit value %q", v)
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return TodoFilter{}, fmt.Errorf("invalid offset value %q", v)
+		}
+		filter.Offset = offset
+	}
+	return filter, nil
+}
+
+// paginationLinkHeader builds an RFC 5988 Link header with "next" and
+// "prev" relations for the current filter, or "" if limit wasn't set (i.e.
+// the caller isn't paginating).
+func paginationLinkHeader(r *http.Request, filter TodoFilter, total int) string {
+	if filter.Limit <= 0 {
+		return ""
+	}
+	linkFor := func(offset int) string {
+		q := r.URL.Query()
+		q.Set("limit", strconv.Itoa(filter.Limit))
+		q.Set("offset", strconv.Itoa(offset))
+		u := *r.URL
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	var links []string
+	if next := filter.Offset + filter.Limit; next < total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, linkFor(next)))
+	}
+	if filter.Offset > 0 {
+		prev := filter.Offset - filter.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, linkFor(prev)))
+	}
+	if len(links) == 0 {
+		return ""
+	}
+	out := links[0]
+	for _, l := range links[1:] {
+		out += ", " + l
+	}
+	return out
+}
+
+// handleBatchCreate backs POST /api/todos:batch: it creates one todo per
+// title and returns them in the same order. Like the single-create path,
+// every title must be non-empty once trimmed.
+func handleBatchCreate(store Storage, user User, w http.ResponseWriter, r *http.Request) {
+	var body []struct {
+		Title string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error":"invalid body, expected an array of {title}"}`, http.StatusBadRequest)
+		return
+	}
+	titles := make([]string, len(body))
+	for i, item := range body {
+		title := strings.TrimSpace(item.Title)
+		if title == "" {
+			http.Error(w, `{"error":"title is required"}`, http.StatusBadRequest)
+			return
+		}
+		titles[i] = title
+	}
+	created := store.BatchAdd(user.ID, titles)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// handleBatchUpdate backs PATCH /api/todos:batch: every id in the request
+// gets the same title/completed fields applied, with per-item results so a
+// missing id doesn't fail the whole batch.
+func handleBatchUpdate(store Storage, user User, w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		IDs       []int   `json:"ids"`
+		Title     *string `json:"title"`
+		Completed *bool   `json:"completed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.IDs) == 0 {
+		http.Error(w, `{"error":"invalid body, expected {ids, title?, completed?}"}`, http.StatusBadRequest)
+		return
+	}
+	results := store.BatchUpdate(user.ID, body.IDs, body.Title, body.Completed)
+	json.NewEncoder(w).Encode(results)
+}
+
+// handleBatchDelete backs DELETE /api/todos:batch: every id in the request
+// is deleted independently, with per-item results.
+func handleBatchDelete(store Storage, user User, w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		IDs []int `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.IDs) == 0 {
+		http.Error(w, `{"error":"invalid body, expected {ids}"}`, http.StatusBadRequest)
+		return
+	}
+	results := store.BatchDelete(user.ID, body.IDs)
+	json.NewEncoder(w).Encode(results)
+}
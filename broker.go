@@ -0,0 +1,91 @@
+package main
+
+import "sync"
+
+// EventKind identifies the mutation a TodoEvent describes.
+type EventKind string
+
+const (
+	EventCreated EventKind = "created"
+	EventUpdated EventKind = "updated"
+	EventDeleted EventKind = "deleted"
+)
+
+// TodoEvent is published whenever a todo is created, updated or deleted, so
+// SSE/WebSocket subscribers can mirror the change without polling.
+type TodoEvent struct {
+	Kind EventKind `json:"kind"`
+	Todo Todo      `json:"todo"`
+}
+
+// subscriberBufferSize bounds how far a slow consumer can fall behind before
+// events for it start being dropped, rather than blocking publishers.
+const subscriberBufferSize = 16
+
+// allOwnersKey is the subscription key used by SubscribeAll, for consumers
+// (like the reminder scheduler) that need every owner's events.
+const allOwnersKey = "*"
+
+// Broker fans out TodoEvents to per-owner subscriber channels. A publish
+// that would block a full subscriber channel drops the event for that
+// subscriber instead of blocking the rest.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan TodoEvent]struct{} // ownerID -> set of channels
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]map[chan TodoEvent]struct{})}
+}
+
+// Subscribe registers a new subscriber for ownerID's events. Call the
+// returned cancel func when done to unregister and close the channel.
+func (b *Broker) Subscribe(ownerID string) (ch chan TodoEvent, cancel func()) {
+	ch = make(chan TodoEvent, subscriberBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[ownerID] == nil {
+		b.subscribers[ownerID] = make(map[chan TodoEvent]struct{})
+	}
+	b.subscribers[ownerID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	cancel = func() {
+		b.mu.Lock()
+		delete(b.subscribers[ownerID], ch)
+		if len(b.subscribers[ownerID]) == 0 {
+			delete(b.subscribers, ownerID)
+		}
+		b.mu.Unlock()
+		close(ch)
+	}
+	return ch, cancel
+}
+
+// SubscribeAll registers a subscriber for every owner's events, for
+// consumers like the reminder scheduler that can't scope to one owner.
+func (b *Broker) SubscribeAll() (ch chan TodoEvent, cancel func()) {
+	return b.Subscribe(allOwnersKey)
+}
+
+// Publish fans ev out to every subscriber for ev.Todo.OwnerID plus every
+// SubscribeAll subscriber. A subscriber whose channel is full has the event
+// dropped rather than blocking this call.
+func (b *Broker) Publish(ev TodoEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[ev.Todo.OwnerID] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	if ev.Todo.OwnerID != allOwnersKey {
+		for ch := range b.subscribers[allOwnersKey] {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestBrokerPublishScopesToOwner(t *testing.T) {
+	b := NewBroker()
+	aliceCh, aliceCancel := b.Subscribe("alice")
+	defer aliceCancel()
+	bobCh, bobCancel := b.Subscribe("bob")
+	defer bobCancel()
+
+	b.Publish(TodoEvent{Kind: EventCreated, Todo: Todo{ID: 1, OwnerID: "alice"}})
+
+	select {
+	case ev := <-aliceCh:
+		if ev.Todo.OwnerID != "alice" {
+			t.Errorf("alice received event for owner %q", ev.Todo.OwnerID)
+		}
+	default:
+		t.Error("alice's subscription did not receive her own event")
+	}
+
+	select {
+	case ev := <-bobCh:
+		t.Errorf("bob should not receive alice's event, got %+v", ev)
+	default:
+	}
+}
+
+func TestBrokerSubscribeAllReceivesEveryOwner(t *testing.T) {
+	b := NewBroker()
+	allCh, cancel := b.SubscribeAll()
+	defer cancel()
+
+	b.Publish(TodoEvent{Kind: EventCreated, Todo: Todo{ID: 1, OwnerID: "alice"}})
+	b.Publish(TodoEvent{Kind: EventUpdated, Todo: Todo{ID: 2, OwnerID: "bob"}})
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case ev := <-allCh:
+			seen[ev.Todo.OwnerID] = true
+		default:
+			t.Fatalf("expected %d events on the all-owners subscription, only saw %d", 2, i)
+		}
+	}
+	if !seen["alice"] || !seen["bob"] {
+		t.Errorf("SubscribeAll missed an owner's event, saw %v", seen)
+	}
+}
+
+func TestBrokerDropsEventsForFullSlowConsumer(t *testing.T) {
+	b := NewBroker()
+	ch, cancel := b.Subscribe("alice")
+	defer cancel()
+
+	// Fill the subscriber's buffer, then publish one more: Publish must not
+	// block even though nothing is draining ch.
+	for i := 0; i < subscriberBufferSize+5; i++ {
+		b.Publish(TodoEvent{Kind: EventCreated, Todo: Todo{ID: i, OwnerID: "alice"}})
+	}
+
+	if got := len(ch); got != subscriberBufferSize {
+		t.Errorf("subscriber channel length = %d, want %d (buffer full, excess dropped)", got, subscriberBufferSize)
+	}
+}
+
+func TestBrokerCancelStopsDelivery(t *testing.T) {
+	b := NewBroker()
+	ch, cancel := b.Subscribe("alice")
+	cancel()
+
+	if _, ok := <-ch; ok {
+		t.Error("expected the channel to be closed after cancel")
+	}
+
+	// Publishing after cancel must not panic even though the channel is closed.
+	b.Publish(TodoEvent{Kind: EventCreated, Todo: Todo{ID: 1, OwnerID: "alice"}})
+}
@@ -0,0 +1,193 @@
+// Package client is a typed Go client for the todo HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Todo mirrors the JSON shape returned by the server. It's defined here
+// rather than imported, since the server is a package main.
+type Todo struct {
+	ID        int       `json:"id"`
+	OwnerID   string    `json:"owner_id"`
+	Title     string    `json:"title"`
+	Completed bool      `json:"completed"`
+	CreatedAt time.Time `json:"created_at"`
+
+	DueAt        *time.Time    `json:"due_at,omitempty"`
+	Recurrence   string        `json:"recurrence,omitempty"`
+	NotifyBefore time.Duration `json:"notify_before,omitempty"`
+
+	ReminderFiredAt *time.Time `json:"reminder_fired_at,omitempty"`
+}
+
+// APIError is returned for any non-2xx response; Message comes from the
+// server's {"error": "..."} envelope.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("todo API: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// Client calls the todo HTTP API with a single API key, minted ahead of
+// time via POST /api/auth/tokens.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	reauth     func(ctx context.Context) (string, error)
+}
+
+// Option configures a Client built by New.
+type Option func(*Client)
+
+// WithAPIKey sets the bearer token sent with every request.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithHTTPClient overrides the default http.Client, e.g. to set a timeout
+// or a custom transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithReauth registers a callback invoked once, automatically, whenever a
+// request comes back 401: it should return a fresh API key to retry with.
+// Without it, a 401 is returned to the caller as an *APIError.
+func WithReauth(f func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) { c.reauth = f }
+}
+
+// New builds a Client for the API rooted at baseURL (e.g. "http://localhost:8080").
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// List returns the caller's todos. Filtering/pagination query parameters
+// are left to callers who need them, via a raw path through do.
+func (c *Client) List(ctx context.Context) ([]Todo, error) {
+	var todos []Todo
+	if err := c.do(ctx, http.MethodGet, "/api/todos", nil, &todos); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// Create adds a new todo with the given title.
+func (c *Client) Create(ctx context.Context, title string) (Todo, error) {
+	var t Todo
+	body := struct {
+		Title string `json:"title"`
+	}{Title: title}
+	err := c.do(ctx, http.MethodPost, "/api/todos", body, &t)
+	return t, err
+}
+
+// Update applies the given fields to todo id; a nil field is left
+// unchanged.
+func (c *Client) Update(ctx context.Context, id int, title *string, completed *bool) (Todo, error) {
+	var t Todo
+	body := struct {
+		Title     *string `json:"title,omitempty"`
+		Completed *bool   `json:"completed,omitempty"`
+	}{Title: title, Completed: completed}
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/todos/%d", id), body, &t)
+	return t, err
+}
+
+// Delete removes todo id.
+func (c *Client) Delete(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/todos/%d", id), nil, nil)
+}
+
+// do marshals body (if any) once and sends it as a seekable *bytes.Reader,
+// so a 401 can be retried against the same request after rewinding the
+// body, instead of re-marshaling it.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+	var bodyReader *bytes.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
+
+	resp, err := c.send(ctx, method, path, bodyReader)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && c.reauth != nil {
+		resp.Body.Close()
+		key, rerr := c.reauth(ctx)
+		if rerr != nil {
+			return &APIError{StatusCode: http.StatusUnauthorized, Message: "authentication failed"}
+		}
+		c.apiKey = key
+		if bodyReader != nil {
+			if _, err := bodyReader.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		resp, err = c.send(ctx, method, path, bodyReader)
+		if err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		msg := e.Error
+		if msg == "" {
+			msg = resp.Status
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: msg}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body *bytes.Reader) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = body
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	return c.httpClient.Do(req)
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCreateListUpdateDelete(t *testing.T) {
+	var todos []Todo
+	nextID := 1
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/todos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(todos)
+		case http.MethodPost:
+			var body struct {
+				Title string `json:"title"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			t := Todo{ID: nextID, Title: body.Title}
+			nextID++
+			todos = append(todos, t)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(t)
+		}
+	})
+	mux.HandleFunc("/api/todos/1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			var body struct {
+				Completed *bool `json:"completed"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			todos[0].Completed = *body.Completed
+			json.NewEncoder(w).Encode(todos[0])
+		case http.MethodDelete:
+			todos = nil
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.URL, WithAPIKey("test-key"))
+	ctx := context.Background()
+
+	created, err := c.Create(ctx, "write tests")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID != 1 || created.Title != "write tests" {
+		t.Errorf("Create returned %+v", created)
+	}
+
+	listed, err := c.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != 1 {
+		t.Errorf("List returned %+v", listed)
+	}
+
+	completed := true
+	updated, err := c.Update(ctx, 1, nil, &completed)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !updated.Completed {
+		t.Errorf("Update did not mark the todo completed: %+v", updated)
+	}
+
+	if err := c.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
+
+func TestClientReturnsAPIErrorOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"not found"}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithAPIKey("test-key"))
+	err := c.Delete(context.Background(), 42)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "not found" {
+		t.Errorf("got %+v, want status 404 and message %q", apiErr, "not found")
+	}
+}
+
+func TestClientReauthRetriesWithRewoundBody(t *testing.T) {
+	var attempt int
+	var gotBodies []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBodies = append(gotBodies, string(body))
+		attempt++
+		if attempt == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"token expired"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fresh-key" {
+			t.Errorf("retried request should use the refreshed key, got %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Todo{ID: 1, Title: "reauthed"})
+	}))
+	defer srv.Close()
+
+	reauthCalled := false
+	c := New(srv.URL, WithAPIKey("stale-key"), WithReauth(func(ctx context.Context) (string, error) {
+		reauthCalled = true
+		return "fresh-key", nil
+	}))
+
+	created, err := c.Create(context.Background(), "reauthed")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !reauthCalled {
+		t.Error("expected the reauth callback to be invoked on a 401")
+	}
+	if attempt != 2 {
+		t.Errorf("expected exactly one retry (2 attempts), got %d", attempt)
+	}
+	if len(gotBodies) != 2 || gotBodies[0] != gotBodies[1] {
+		t.Errorf("retried request body should match the original after rewinding, got %v", gotBodies)
+	}
+	if created.Title != "reauthed" {
+		t.Errorf("Create returned %+v", created)
+	}
+}
@@ -0,0 +1,116 @@
+// Command todo is a CLI front-end for the todo API, built on the client
+// package: todo list|add|complete|delete.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/juntakoman123/todo/client"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "todo:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: todo <list|add|complete|delete> [args]")
+	}
+
+	apiURL := os.Getenv("TODO_API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:8080"
+	}
+	apiKey := os.Getenv("TODO_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("TODO_API_KEY must be set to an API key minted via POST /api/auth/tokens")
+	}
+	c := client.New(apiURL, client.WithAPIKey(apiKey))
+	ctx := context.Background()
+
+	switch cmd, rest := args[0], args[1:]; cmd {
+	case "list":
+		return runList(ctx, c)
+	case "add":
+		return runAdd(ctx, c, rest)
+	case "complete":
+		return runComplete(ctx, c, rest)
+	case "delete":
+		return runDelete(ctx, c, rest)
+	default:
+		return fmt.Errorf("unknown command %q: usage: todo <list|add|complete|delete> [args]", cmd)
+	}
+}
+
+func runList(ctx context.Context, c *client.Client) error {
+	todos, err := c.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range todos {
+		status := " "
+		if t.Completed {
+			status = "x"
+		}
+		fmt.Printf("[%s] %d  %s\n", status, t.ID, t.Title)
+	}
+	return nil
+}
+
+func runAdd(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: todo add <title>")
+	}
+	t, err := c.Create(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created todo %d: %s\n", t.ID, t.Title)
+	return nil
+}
+
+func runComplete(ctx context.Context, c *client.Client, args []string) error {
+	id, err := parseID("complete", args)
+	if err != nil {
+		return err
+	}
+	completed := true
+	t, err := c.Update(ctx, id, nil, &completed)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("completed todo %d: %s\n", t.ID, t.Title)
+	return nil
+}
+
+func runDelete(ctx context.Context, c *client.Client, args []string) error {
+	id, err := parseID("delete", args)
+	if err != nil {
+		return err
+	}
+	if err := c.Delete(ctx, id); err != nil {
+		return err
+	}
+	fmt.Printf("deleted todo %d\n", id)
+	return nil
+}
+
+func parseID(cmd string, args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("usage: todo %s <id>", cmd)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", args[0])
+	}
+	return id, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const keepaliveInterval = 15 * time.Second
+
+var upgrader = websocket.Upgrader{
+	// The UI is served from the same origin as the API, so the default
+	// same-origin check is sufficient here.
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// handleTodoEventsSSE backs GET /api/todos/events: a text/event-stream feed
+// of the authenticated user's todo mutations, with periodic keepalive
+// comments so proxies don't time the connection out.
+func handleTodoEventsSSE(broker *Broker, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _ := userFromContext(r)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, `{"error":"streaming unsupported"}`, http.StatusInternalServerError)
+			return
+		}
+
+		ch, cancel := broker.Subscribe(user.ID)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepalive := time.NewTicker(keepaliveInterval)
+		defer keepalive.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepalive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+				flusher.Flush()
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(ev)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Kind, data)
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// handleTodoEventsWS backs GET /api/todos/ws: the same event feed as the SSE
+// endpoint, upgraded to a WebSocket for clients that want a bidirectional
+// connection. Inbound messages are ignored; pings keep the connection alive
+// through idle proxies.
+func handleTodoEventsWS(broker *Broker, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _ := userFromContext(r)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Warn("websocket upgrade failed", "err", err)
+			return
+		}
+		defer conn.Close()
+
+		ch, cancel := broker.Subscribe(user.ID)
+		defer cancel()
+
+		// Drain and discard whatever the client sends, so the read side
+		// notices disconnects (gorilla/websocket requires a reader to be
+		// running to detect close frames).
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		keepalive := time.NewTicker(keepaliveInterval)
+		defer keepalive.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-keepalive.C:
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				if err := conn.WriteJSON(ev); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
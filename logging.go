@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// defaultLogMaxBytes is the file size at which rotatingWriter rolls the log
+// file over, used unless LOG_MAX_BYTES overrides it.
+const defaultLogMaxBytes = 10 * 1024 * 1024
+
+// newLogger builds the process-wide structured logger. It always writes JSON
+// lines to stdout and, when logPath is non-empty, tees the same lines to a
+// file that rotates once it passes LOG_MAX_BYTES bytes (10MiB by default),
+// so operators can tail logs without shelling into the container and without
+// the file growing without bound.
+func newLogger(logPath string) *slog.Logger {
+	level := parseLogLevel(os.Getenv("LOG_LEVEL"))
+	out := io.Writer(os.Stdout)
+	if logPath != "" {
+		maxBytes := int64(defaultLogMaxBytes)
+		if v := os.Getenv("LOG_MAX_BYTES"); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+				maxBytes = n
+			}
+		}
+		f, err := newRotatingWriter(logPath, maxBytes)
+		if err != nil {
+			slog.Warn("could not open log file, logging to stdout only", "path", logPath, "err", err)
+		} else {
+			out = io.MultiWriter(os.Stdout, f)
+		}
+	}
+	handler := slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level})
+	return slog.New(handler)
+}
+
+// rotatingWriter is an io.Writer over a file that renames the file aside
+// once it reaches maxBytes and starts a fresh one, keeping a single
+// rotated backup at path+".1".
+type rotatingWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+func newRotatingWriter(path string, maxBytes int64) (*rotatingWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	return &rotatingWriter{path: path, maxBytes: maxBytes, file: f, size: size}, nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.size = 0
+	return nil
+}
+
+func parseLogLevel(s string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// requestID returns the UUID injected by withRequestLogging, or "" if the
+// context was not built by it (e.g. in tests that call handlers directly).
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// newRequestID generates a random UUIDv4 without pulling in an external
+// dependency.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush and Hijack forward to the underlying ResponseWriter so handlers
+// wrapped by withRequestLogging can still stream (SSE) or take over the
+// raw connection (WebSocket upgrades).
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+// withRequestLogging wraps an http.HandlerFunc with request ID propagation
+// and a structured access log entry written once the handler returns.
+func withRequestLogging(logger *slog.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := newRequestID()
+		w.Header().Set("X-Request-ID", id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		r = r.WithContext(ctx)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next(rec, r)
+
+		logger.Info("request",
+			"request_id", id,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"remote_addr", r.RemoteAddr,
+		)
+	}
+}
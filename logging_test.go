@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingWriterRotatesOnceOverLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := newRotatingWriter(path, 50)
+	if err != nil {
+		t.Fatalf("newRotatingWriter: %v", err)
+	}
+
+	line := []byte("0123456789\n") // 11 bytes
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat current log: %v", err)
+	}
+	if info.Size() > 50 {
+		t.Errorf("current log file grew past maxBytes: got %d bytes, want <= 50", info.Size())
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected a rotated backup at %s: %v", path+".1", err)
+	}
+}
+
+func TestRotatingWriterNoRotationUnderLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := newRotatingWriter(path, 1<<20)
+	if err != nil {
+		t.Fatalf("newRotatingWriter: %v", err)
+	}
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("did not expect a rotated backup, got err=%v", err)
+	}
+}
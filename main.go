@@ -1,131 +1,142 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
 type Todo struct {
 	ID        int       `json:"id"`
+	OwnerID   string    `json:"owner_id"`
 	Title     string    `json:"title"`
 	Completed bool      `json:"completed"`
 	CreatedAt time.Time `json:"created_at"`
-}
 
-type Store struct {
-	mu       sync.Mutex
-	todos    []Todo
-	nextID   int
-	filepath string
+	// DueAt, Recurrence and NotifyBefore drive the reminder scheduler (see
+	// scheduler.go). Recurrence is an RFC 5545 RRULE subset, e.g.
+	// "FREQ=DAILY;INTERVAL=1".
+	DueAt        *time.Time    `json:"due_at,omitempty"`
+	Recurrence   string        `json:"recurrence,omitempty"`
+	NotifyBefore time.Duration `json:"notify_before,omitempty"`
+
+	// ReminderFiredAt is the watermark set once a reminder has been
+	// dispatched for the current DueAt, so the scheduler doesn't notify
+	// twice across restarts.
+	ReminderFiredAt *time.Time `json:"reminder_fired_at,omitempty"`
 }
 
-func NewStore(filepath string) *Store {
-	s := &Store{filepath: filepath, nextID: 1}
-	s.load()
-	return s
+func defaultStorageDSN() string {
+	if dsn := os.Getenv("STORAGE_DSN"); dsn != "" {
+		return dsn
+	}
+	return "file://todos.json"
 }
 
-func (s *Store) load() {
-	data, err := os.ReadFile(s.filepath)
-	if err != nil {
-		return
-	}
-	if err := json.Unmarshal(data, &s.todos); err != nil {
-		return
-	}
-	for _, t := range s.todos {
-		if t.ID >= s.nextID {
-			s.nextID = t.ID + 1
+func main() {
+	storageDSN := flag.String("storage", defaultStorageDSN(), `storage backend DSN, e.g. "file://todos.json" or "sqlite:///data/todos.db"`)
+	convertFrom := flag.String("convert-from", "", "DSN to read todos from, then exit (use with --convert-to)")
+	convertTo := flag.String("convert-to", "", "DSN to write todos to, then exit (use with --convert-from)")
+	flag.Parse()
+
+	logger := newLogger(os.Getenv("LOG_FILE"))
+	slog.SetDefault(logger)
+
+	if *convertFrom != "" || *convertTo != "" {
+		if *convertFrom == "" || *convertTo == "" {
+			logger.Error("both --convert-from and --convert-to are required")
+			os.Exit(1)
+		}
+		if err := runConvert(*convertFrom, *convertTo); err != nil {
+			logger.Error("conversion failed", "err", err)
+			os.Exit(1)
 		}
+		return
 	}
-}
 
-func (s *Store) save() error {
-	data, err := json.MarshalIndent(s.todos, "", "  ")
+	base, err := openStorage(*storageDSN)
 	if err != nil {
-		return err
+		logger.Error("failed to open storage", "dsn", *storageDSN, "err", err)
+		os.Exit(1)
 	}
-	return os.WriteFile(s.filepath, data, 0644)
-}
-
-func (s *Store) All() []Todo {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	result := make([]Todo, len(s.todos))
-	copy(result, s.todos)
-	return result
-}
-
-func (s *Store) Add(title string) Todo {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	t := Todo{
-		ID:        s.nextID,
-		Title:     title,
-		Completed: false,
-		CreatedAt: time.Now(),
+	baseLister, ok := base.(unscopedLister)
+	if !ok {
+		logger.Error("storage backend does not support the reminder scheduler", "dsn", *storageDSN)
+		os.Exit(1)
 	}
-	s.nextID++
-	s.todos = append(s.todos, t)
-	s.save()
-	return t
-}
 
-func (s *Store) Update(id int, title *string, completed *bool) (Todo, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for i, t := range s.todos {
-		if t.ID == id {
-			if title != nil {
-				s.todos[i].Title = *title
-			}
-			if completed != nil {
-				s.todos[i].Completed = *completed
-			}
-			s.save()
-			return s.todos[i], true
-		}
-	}
-	return Todo{}, false
-}
+	broker := NewBroker()
+	var store Storage = NewEventingStorage(base, broker)
+	store = NewRecurringStorage(store, logger)
 
-func (s *Store) Delete(id int) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for i, t := range s.todos {
-		if t.ID == id {
-			s.todos = append(s.todos[:i], s.todos[i+1:]...)
-			s.save()
-			return true
-		}
-	}
-	return false
-}
+	notifier := newNotifierFromEnv(logger)
+	scheduler := NewScheduler(store, baseLister, notifier, broker, logger)
+	go scheduler.Run(context.Background())
 
-func main() {
-	store := NewStore("todos.json")
+	users := NewUserStore("users.json")
+	if err := users.bootstrapAdmin(os.Getenv("ADMIN_TOKEN")); err != nil {
+		logger.Error("failed to bootstrap admin token", "err", err)
+	}
+	limiter := NewRateLimiter(5, 10)
 
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	http.HandleFunc("/openapi.json", withRequestLogging(logger, handleOpenAPISpec))
+	http.HandleFunc("/docs", withRequestLogging(logger, handleSwaggerUI))
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/", withRequestLogging(logger, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
 			http.NotFound(w, r)
 			return
 		}
 		http.ServeFile(w, r, "static/index.html")
-	})
+	}))
 
-	http.HandleFunc("/api/todos", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+	http.HandleFunc("/api/auth/tokens", withRequestLogging(logger, requireAuth(users, limiter, requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+			return
+		}
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+			http.Error(w, `{"error":"name is required"}`, http.StatusBadRequest)
+			return
+		}
+		u, key, err := users.Create(strings.TrimSpace(body.Name))
+		if err != nil {
+			http.Error(w, `{"error":"could not mint token"}`, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(struct {
+			User
+			Key string `json:"key"`
+		}{User: u, Key: key})
+	}))))
+
+	http.HandleFunc("/api/todos", withRequestLogging(logger, requireAuth(users, limiter, func(w http.ResponseWriter, r *http.Request) {
+		user, _ := userFromContext(r)
 		switch r.Method {
 		case http.MethodGet:
-			json.NewEncoder(w).Encode(store.All())
+			filter, err := parseTodoFilter(r.URL.Query())
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+				return
+			}
+			todos, total := store.Query(user.ID, filter)
+			w.Header().Set("X-Total-Count", strconv.Itoa(total))
+			if link := paginationLinkHeader(r, filter, total); link != "" {
+				w.Header().Set("Link", link)
+			}
+			json.NewEncoder(w).Encode(todos)
 		case http.MethodPost:
 			var body struct {
 				Title string `json:"title"`
@@ -134,16 +145,30 @@ func main() {
 				http.Error(w, `{"error":"title is required"}`, http.StatusBadRequest)
 				return
 			}
-			t := store.Add(strings.TrimSpace(body.Title))
+			t := store.Add(user.ID, strings.TrimSpace(body.Title))
 			w.WriteHeader(http.StatusCreated)
 			json.NewEncoder(w).Encode(t)
 		default:
 			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
 		}
-	})
+	})))
+
+	http.HandleFunc("/api/todos:batch", withRequestLogging(logger, requireAuth(users, limiter, func(w http.ResponseWriter, r *http.Request) {
+		user, _ := userFromContext(r)
+		switch r.Method {
+		case http.MethodPost:
+			handleBatchCreate(store, user, w, r)
+		case http.MethodPatch:
+			handleBatchUpdate(store, user, w, r)
+		case http.MethodDelete:
+			handleBatchDelete(store, user, w, r)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+	})))
 
-	http.HandleFunc("/api/todos/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+	http.HandleFunc("/api/todos/", withRequestLogging(logger, requireAuth(users, limiter, func(w http.ResponseWriter, r *http.Request) {
+		user, _ := userFromContext(r)
 		idStr := strings.TrimPrefix(r.URL.Path, "/api/todos/")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
@@ -153,21 +178,47 @@ func main() {
 		switch r.Method {
 		case http.MethodPut:
 			var body struct {
-				Title     *string `json:"title"`
-				Completed *bool   `json:"completed"`
+				Title        *string        `json:"title"`
+				Completed    *bool          `json:"completed"`
+				DueAt        *time.Time     `json:"due_at"`
+				Recurrence   *string        `json:"recurrence"`
+				NotifyBefore *time.Duration `json:"notify_before"`
 			}
 			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 				http.Error(w, `{"error":"invalid body"}`, http.StatusBadRequest)
 				return
 			}
-			t, ok := store.Update(id, body.Title, body.Completed)
+			if body.Recurrence != nil && *body.Recurrence != "" {
+				if _, _, err := parseRecurrence(*body.Recurrence); err != nil {
+					http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+					return
+				}
+			}
+			t, ok := store.Update(user.ID, id, body.Title, body.Completed)
 			if !ok {
 				http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 				return
 			}
+			if body.DueAt != nil || body.Recurrence != nil || body.NotifyBefore != nil {
+				dueAt, recurrence, notifyBefore := t.DueAt, t.Recurrence, t.NotifyBefore
+				if body.DueAt != nil {
+					dueAt = body.DueAt
+				}
+				if body.Recurrence != nil {
+					recurrence = *body.Recurrence
+				}
+				if body.NotifyBefore != nil {
+					notifyBefore = *body.NotifyBefore
+				}
+				t, ok = store.SetSchedule(user.ID, id, dueAt, recurrence, notifyBefore)
+				if !ok {
+					http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+					return
+				}
+			}
 			json.NewEncoder(w).Encode(t)
 		case http.MethodDelete:
-			if !store.Delete(id) {
+			if !store.Delete(user.ID, id) {
 				http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 				return
 			}
@@ -175,8 +226,28 @@ func main() {
 		default:
 			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
 		}
-	})
+	})))
+
+	http.HandleFunc("/api/todos/upcoming", withRequestLogging(logger, requireAuth(users, limiter, func(w http.ResponseWriter, r *http.Request) {
+		user, _ := userFromContext(r)
+		within := 24 * time.Hour
+		if v := r.URL.Query().Get("within"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error":"invalid within value %q"}`, v), http.StatusBadRequest)
+				return
+			}
+			within = d
+		}
+		json.NewEncoder(w).Encode(store.Upcoming(user.ID, within))
+	})))
+
+	http.HandleFunc("/api/todos/events", withRequestLogging(logger, requireAuth(users, limiter, handleTodoEventsSSE(broker, logger))))
+	http.HandleFunc("/api/todos/ws", withRequestLogging(logger, requireAuth(users, limiter, handleTodoEventsWS(broker, logger))))
 
-	log.Println("Server started on http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	logger.Info("server started", "addr", "http://localhost:8080")
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		logger.Error("server exited", "err", err)
+		os.Exit(1)
+	}
 }
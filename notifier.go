@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// newNotifierFromEnv builds the Notifier named by NOTIFIER ("stdout" by
+// default): "webhook" reads WEBHOOK_URL, "smtp" reads SMTP_ADDR, SMTP_USER,
+// SMTP_PASSWORD, SMTP_FROM and SMTP_TO.
+func newNotifierFromEnv(logger *slog.Logger) Notifier {
+	switch os.Getenv("NOTIFIER") {
+	case "webhook":
+		return NewWebhookNotifier(os.Getenv("WEBHOOK_URL"))
+	case "smtp":
+		return NewSMTPNotifier(
+			os.Getenv("SMTP_ADDR"), os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("SMTP_FROM"), os.Getenv("SMTP_TO"),
+		)
+	default:
+		return StdoutNotifier{Logger: logger}
+	}
+}
+
+// Notifier dispatches a due-date reminder for t. Implementations should
+// treat ctx's deadline/cancellation as advisory, not ignore it.
+type Notifier interface {
+	Notify(ctx context.Context, t Todo) error
+}
+
+// StdoutNotifier logs reminders through slog instead of sending them
+// anywhere; it's the default so the scheduler works out of the box.
+type StdoutNotifier struct {
+	Logger *slog.Logger
+}
+
+func (n StdoutNotifier) Notify(ctx context.Context, t Todo) error {
+	n.Logger.Info("todo reminder", "todo_id", t.ID, "owner_id", t.OwnerID, "title", t.Title, "due_at", t.DueAt)
+	return nil
+}
+
+// WebhookNotifier POSTs a JSON reminder payload to URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, t Todo) error {
+	body, err := json.Marshal(struct {
+		Event string `json:"event"`
+		Todo  Todo   `json:"todo"`
+	}{Event: "reminder", Todo: t})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook notifier: %s returned %s", n.URL, resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier emails a reminder through a plain SMTP relay.
+type SMTPNotifier struct {
+	Addr string
+	Auth smtp.Auth
+	From string
+	To   string
+}
+
+func NewSMTPNotifier(addr, username, password, from, to string) *SMTPNotifier {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return &SMTPNotifier{
+		Addr: addr,
+		Auth: smtp.PlainAuth("", username, password, host),
+		From: from,
+		To:   to,
+	}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, t Todo) error {
+	subject := fmt.Sprintf("Reminder: %s", sanitizeHeaderValue(t.Title))
+	msg := fmt.Sprintf("Subject: %s\r\n\r\n%q is due at %s\r\n", subject, t.Title, t.DueAt.Format(time.RFC3339))
+	return smtp.SendMail(n.Addr, n.Auth, n.From, []string{n.To}, []byte(msg))
+}
+
+// sanitizeHeaderValue strips CR and LF from s so it can't inject extra
+// headers (or a premature end-of-headers blank line) into a raw SMTP
+// message built by hand, e.g. via a todo titled "Hi\r\nBcc: evil@example.com".
+func sanitizeHeaderValue(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\n", "")
+	return s
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// buildOpenAPISpec describes the /api/todos surface as an OpenAPI 3.1
+// document. It's rebuilt on every request to /openapi.json rather than
+// cached, since it's cheap to construct and this keeps the handler free of
+// init-order concerns.
+func buildOpenAPISpec() map[string]any {
+	todoSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":                map[string]any{"type": "integer"},
+			"owner_id":          map[string]any{"type": "string"},
+			"title":             map[string]any{"type": "string"},
+			"completed":         map[string]any{"type": "boolean"},
+			"created_at":        map[string]any{"type": "string", "format": "date-time"},
+			"due_at":            map[string]any{"type": []string{"string", "null"}, "format": "date-time"},
+			"recurrence":        map[string]any{"type": "string", "description": `RFC 5545 RRULE subset, e.g. "FREQ=DAILY;INTERVAL=1"`},
+			"notify_before":     map[string]any{"type": "integer", "description": "lead time in nanoseconds, as a Go time.Duration"},
+			"reminder_fired_at": map[string]any{"type": []string{"string", "null"}, "format": "date-time"},
+		},
+		"required": []string{"id", "owner_id", "title", "completed", "created_at"},
+	}
+
+	errorSchema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"error": map[string]any{"type": "string"}},
+		"required":   []string{"error"},
+	}
+
+	batchResultSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":    map[string]any{"type": "integer"},
+			"todo":  todoSchema,
+			"error": map[string]any{"type": "string"},
+		},
+		"required": []string{"id"},
+	}
+
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+			},
+		}
+	}
+
+	jsonBody := func(schema map[string]any) map[string]any {
+		return map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": schema}},
+		}
+	}
+
+	jsonResponse := func(description string, schema map[string]any) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+		}
+	}
+
+	idParam := map[string]any{
+		"name": "id", "in": "path", "required": true,
+		"schema": map[string]any{"type": "integer"},
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "todo API",
+			"version": "1.0.0",
+			"description": "A per-owner todo list with filtering, batch operations, " +
+				"recurring schedules and live updates.",
+		},
+		"servers": []map[string]any{{"url": "/"}},
+		"security": []map[string]any{
+			{"bearerAuth": []string{}},
+		},
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "API key minted by POST /api/auth/tokens, passed as Authorization: Bearer <key>.",
+				},
+			},
+			"schemas": map[string]any{
+				"Todo":        todoSchema,
+				"Error":       errorSchema,
+				"BatchResult": batchResultSchema,
+			},
+		},
+		"paths": map[string]any{
+			"/api/auth/tokens": map[string]any{
+				"post": map[string]any{
+					"summary": "Mint a new API key",
+					"requestBody": jsonBody(map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"name": map[string]any{"type": "string"}},
+						"required":   []string{"name"},
+					}),
+					"responses": map[string]any{
+						"201": jsonResponse("the new user and its plaintext key (shown once)", map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"id": map[string]any{"type": "string"}, "name": map[string]any{"type": "string"},
+								"created_at": map[string]any{"type": "string", "format": "date-time"},
+								"key":        map[string]any{"type": "string"},
+							},
+						}),
+						"400": errorResponse("missing name"),
+					},
+				},
+			},
+			"/api/todos": map[string]any{
+				"get": map[string]any{
+					"summary": "List the caller's todos",
+					"parameters": []map[string]any{
+						{"name": "completed", "in": "query", "schema": map[string]any{"type": "boolean"}},
+						{"name": "q", "in": "query", "description": "substring search over title", "schema": map[string]any{"type": "string"}},
+						{"name": "sort", "in": "query", "description": `"created_at" or "title", optionally prefixed with "-"`, "schema": map[string]any{"type": "string"}},
+						{"name": "limit", "in": "query", "schema": map[string]any{"type": "integer"}},
+						{"name": "offset", "in": "query", "schema": map[string]any{"type": "integer"}},
+					},
+					"responses": map[string]any{
+						"200": jsonResponse("matching todos; X-Total-Count and Link headers carry pagination", map[string]any{
+							"type": "array", "items": map[string]any{"$ref": "#/components/schemas/Todo"},
+						}),
+						"400": errorResponse("invalid filter parameter"),
+					},
+				},
+				"post": map[string]any{
+					"summary": "Create a todo",
+					"requestBody": jsonBody(map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"title": map[string]any{"type": "string"}},
+						"required":   []string{"title"},
+					}),
+					"responses": map[string]any{
+						"201": jsonResponse("the created todo", map[string]any{"$ref": "#/components/schemas/Todo"}),
+						"400": errorResponse("missing title"),
+					},
+				},
+			},
+			"/api/todos/{id}": map[string]any{
+				"put": map[string]any{
+					"summary":    "Update a todo's title, completion, or schedule",
+					"parameters": []map[string]any{idParam},
+					"requestBody": jsonBody(map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"title":         map[string]any{"type": "string"},
+							"completed":     map[string]any{"type": "boolean"},
+							"due_at":        map[string]any{"type": []string{"string", "null"}, "format": "date-time"},
+							"recurrence":    map[string]any{"type": "string"},
+							"notify_before": map[string]any{"type": "integer"},
+						},
+					}),
+					"responses": map[string]any{
+						"200": jsonResponse("the updated todo", map[string]any{"$ref": "#/components/schemas/Todo"}),
+						"400": errorResponse("invalid body or recurrence rule"),
+						"404": errorResponse("no such todo"),
+					},
+				},
+				"delete": map[string]any{
+					"summary":    "Delete a todo",
+					"parameters": []map[string]any{idParam},
+					"responses": map[string]any{
+						"204": map[string]any{"description": "deleted"},
+						"404": errorResponse("no such todo"),
+					},
+				},
+			},
+			"/api/todos:batch": map[string]any{
+				"post": map[string]any{
+					"summary": "Create several todos at once",
+					"requestBody": jsonBody(map[string]any{
+						"type":  "array",
+						"items": map[string]any{"type": "object", "properties": map[string]any{"title": map[string]any{"type": "string"}}},
+					}),
+					"responses": map[string]any{
+						"201": jsonResponse("the created todos, in request order", map[string]any{
+							"type": "array", "items": map[string]any{"$ref": "#/components/schemas/Todo"},
+						}),
+						"400": errorResponse("invalid body"),
+					},
+				},
+				"patch": map[string]any{
+					"summary": "Apply the same title/completed fields to several todos",
+					"requestBody": jsonBody(map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"ids":       map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+							"title":     map[string]any{"type": "string"},
+							"completed": map[string]any{"type": "boolean"},
+						},
+						"required": []string{"ids"},
+					}),
+					"responses": map[string]any{
+						"200": jsonResponse("one result per id", map[string]any{
+							"type": "array", "items": map[string]any{"$ref": "#/components/schemas/BatchResult"},
+						}),
+						"400": errorResponse("invalid body"),
+					},
+				},
+				"delete": map[string]any{
+					"summary": "Delete several todos at once",
+					"requestBody": jsonBody(map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"ids": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}},
+						"required":   []string{"ids"},
+					}),
+					"responses": map[string]any{
+						"200": jsonResponse("one result per id", map[string]any{
+							"type": "array", "items": map[string]any{"$ref": "#/components/schemas/BatchResult"},
+						}),
+						"400": errorResponse("invalid body"),
+					},
+				},
+			},
+			"/api/todos/upcoming": map[string]any{
+				"get": map[string]any{
+					"summary": "List todos due soon",
+					"parameters": []map[string]any{
+						{"name": "within", "in": "query", "description": `a Go duration string, e.g. "1h"; defaults to "24h"`, "schema": map[string]any{"type": "string"}},
+					},
+					"responses": map[string]any{
+						"200": jsonResponse("todos due within the window", map[string]any{
+							"type": "array", "items": map[string]any{"$ref": "#/components/schemas/Todo"},
+						}),
+						"400": errorResponse("invalid within value"),
+					},
+				},
+			},
+			"/api/todos/events": map[string]any{
+				"get": map[string]any{
+					"summary":     "Subscribe to todo mutations over Server-Sent Events",
+					"description": "Streams text/event-stream frames; not representable as a typed OpenAPI response.",
+					"responses":   map[string]any{"200": map[string]any{"description": "an open event stream"}},
+				},
+			},
+			"/api/todos/ws": map[string]any{
+				"get": map[string]any{
+					"summary":     "Subscribe to todo mutations over a WebSocket",
+					"description": "Upgrades to a WebSocket connection; not representable as a typed OpenAPI response.",
+					"responses":   map[string]any{"101": map[string]any{"description": "switching protocols"}},
+				},
+			},
+		},
+	}
+}
+
+func handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildOpenAPISpec())
+}
+
+// handleSwaggerUI serves a minimal Swagger UI page pointed at /openapi.json,
+// loading swagger-ui-dist from a CDN instead of vendoring it.
+func handleSwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>todo API docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = () => SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"})
+  </script>
+</body>
+</html>
+`
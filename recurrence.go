@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseRecurrence parses the small RFC 5545 RRULE subset this app supports:
+// "FREQ=DAILY|WEEKLY|MONTHLY[;INTERVAL=n]". INTERVAL defaults to 1.
+func parseRecurrence(rule string) (freq string, interval int, err error) {
+	interval = 1
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return "", 0, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return "", 0, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			interval = n
+		default:
+			return "", 0, fmt.Errorf("unsupported recurrence field %q", key)
+		}
+	}
+	switch freq {
+	case "DAILY", "WEEKLY", "MONTHLY":
+	default:
+		return "", 0, fmt.Errorf("unsupported FREQ %q, want DAILY, WEEKLY or MONTHLY", freq)
+	}
+	return freq, interval, nil
+}
+
+// nextOccurrence computes the next time rule fires after from.
+func nextOccurrence(from time.Time, rule string) (time.Time, error) {
+	freq, interval, err := parseRecurrence(rule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch freq {
+	case "DAILY":
+		return from.AddDate(0, 0, interval), nil
+	case "WEEKLY":
+		return from.AddDate(0, 0, 7*interval), nil
+	case "MONTHLY":
+		return from.AddDate(0, interval, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported FREQ %q", freq)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	cases := []struct {
+		rule         string
+		wantFreq     string
+		wantInterval int
+		wantErr      bool
+	}{
+		{"FREQ=DAILY", "DAILY", 1, false},
+		{"FREQ=WEEKLY;INTERVAL=2", "WEEKLY", 2, false},
+		{"freq=monthly;interval=3", "MONTHLY", 3, false},
+		{"FREQ=YEARLY", "", 0, true},
+		{"FREQ=DAILY;INTERVAL=0", "", 0, true},
+		{"FREQ=DAILY;INTERVAL=-1", "", 0, true},
+		{"INTERVAL=2", "", 0, true},
+		{"FREQ=DAILY;BOGUS=1", "", 0, true},
+		{"not a rule", "", 0, true},
+	}
+	for _, c := range cases {
+		freq, interval, err := parseRecurrence(c.rule)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseRecurrence(%q): expected an error, got freq=%q interval=%d", c.rule, freq, interval)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRecurrence(%q): unexpected error: %v", c.rule, err)
+			continue
+		}
+		if freq != c.wantFreq || interval != c.wantInterval {
+			t.Errorf("parseRecurrence(%q) = (%q, %d), want (%q, %d)", c.rule, freq, interval, c.wantFreq, c.wantInterval)
+		}
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	from := time.Date(2026, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	next, err := nextOccurrence(from, "FREQ=DAILY;INTERVAL=1")
+	if err != nil {
+		t.Fatalf("nextOccurrence DAILY: %v", err)
+	}
+	if want := from.AddDate(0, 0, 1); !next.Equal(want) {
+		t.Errorf("DAILY next = %v, want %v", next, want)
+	}
+
+	next, err = nextOccurrence(from, "FREQ=WEEKLY;INTERVAL=2")
+	if err != nil {
+		t.Fatalf("nextOccurrence WEEKLY: %v", err)
+	}
+	if want := from.AddDate(0, 0, 14); !next.Equal(want) {
+		t.Errorf("WEEKLY next = %v, want %v", next, want)
+	}
+
+	next, err = nextOccurrence(from, "FREQ=MONTHLY;INTERVAL=1")
+	if err != nil {
+		t.Fatalf("nextOccurrence MONTHLY: %v", err)
+	}
+	if want := from.AddDate(0, 1, 0); !next.Equal(want) {
+		t.Errorf("MONTHLY next = %v, want %v", next, want)
+	}
+
+	if _, err := nextOccurrence(from, "FREQ=BOGUS"); err == nil {
+		t.Error("expected an error for an unsupported FREQ")
+	}
+}
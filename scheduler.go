@@ -0,0 +1,140 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const schedulerTick = time.Minute
+
+// reminderItem is one entry in the scheduler's min-heap: the next time a
+// todo's reminder should fire.
+type reminderItem struct {
+	ownerID string
+	todoID  int
+	fireAt  time.Time
+}
+
+// reminderHeap orders reminderItems by fireAt, earliest first.
+type reminderHeap []reminderItem
+
+func (h reminderHeap) Len() int           { return len(h) }
+func (h reminderHeap) Less(i, j int) bool { return h[i].fireAt.Before(h[j].fireAt) }
+func (h reminderHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *reminderHeap) Push(x any)        { *h = append(*h, x.(reminderItem)) }
+func (h *reminderHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// Scheduler dispatches due-date reminders. It maintains a min-heap of
+// pending reminders built from broker events, so each tick only has to look
+// at the heap's earliest entries instead of re-scanning every todo.
+//
+// The heap supports no in-place update, so rescheduling a todo (or simply
+// republishing it via an unrelated field edit) pushes another entry rather
+// than replacing the old one. pending tracks each todo's one true current
+// fireAt so fireDue can lazily discard any heap entry that's been
+// superseded instead of firing it twice.
+type Scheduler struct {
+	store    Storage
+	seed     unscopedLister
+	notifier Notifier
+	broker   *Broker
+	logger   *slog.Logger
+	heap     reminderHeap
+	pending  map[string]time.Time
+}
+
+// NewScheduler builds a Scheduler. seed is used once at startup to rebuild
+// the heap from persisted state across every owner (the Storage interface
+// itself is always owner-scoped).
+func NewScheduler(store Storage, seed unscopedLister, notifier Notifier, broker *Broker, logger *slog.Logger) *Scheduler {
+	return &Scheduler{store: store, seed: seed, notifier: notifier, broker: broker, logger: logger, pending: make(map[string]time.Time)}
+}
+
+// reminderKey identifies a todo's slot in pending, independent of any one
+// heap entry.
+func reminderKey(ownerID string, todoID int) string {
+	return fmt.Sprintf("%s:%d", ownerID, todoID)
+}
+
+// Run seeds the heap from persisted todos, then processes broker events and
+// a once-a-minute tick until ctx is canceled.
+func (s *Scheduler) Run(ctx context.Context) {
+	heap.Init(&s.heap)
+	for _, t := range s.seed.allUnscoped() {
+		s.schedule(t)
+	}
+
+	events, cancel := s.broker.SubscribeAll()
+	defer cancel()
+
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-events:
+			s.schedule(ev.Todo)
+		case <-ticker.C:
+			s.fireDue(ctx)
+		}
+	}
+}
+
+// schedule pushes a heap entry for t's next reminder, if it's eligible: has
+// a DueAt, a positive NotifyBefore, isn't completed, and hasn't already
+// fired. SetSchedule always clears ReminderFiredAt when the due date
+// changes, so a non-nil ReminderFiredAt means this exact reminder already
+// went out.
+func (s *Scheduler) schedule(t Todo) {
+	if t.Completed || t.DueAt == nil || t.NotifyBefore <= 0 || t.ReminderFiredAt != nil {
+		return
+	}
+	fireAt := t.DueAt.Add(-t.NotifyBefore)
+	key := reminderKey(t.OwnerID, t.ID)
+	s.pending[key] = fireAt
+	heap.Push(&s.heap, reminderItem{ownerID: t.OwnerID, todoID: t.ID, fireAt: fireAt})
+}
+
+// fireDue pops and dispatches every heap entry whose fire time has passed.
+// Each entry is re-validated against current store state before firing,
+// since the heap can hold stale entries for todos that were since
+// completed, deleted, or rescheduled.
+func (s *Scheduler) fireDue(ctx context.Context) {
+	now := time.Now()
+	for s.heap.Len() > 0 && !s.heap[0].fireAt.After(now) {
+		item := heap.Pop(&s.heap).(reminderItem)
+
+		key := reminderKey(item.ownerID, item.todoID)
+		if want, ok := s.pending[key]; !ok || !want.Equal(item.fireAt) {
+			continue // stale: superseded by a later schedule() call, or already fired
+		}
+		delete(s.pending, key)
+
+		t, ok := s.store.Get(item.ownerID, item.todoID)
+		if !ok || t.Completed || t.DueAt == nil || t.ReminderFiredAt != nil {
+			continue
+		}
+		if !t.DueAt.Add(-t.NotifyBefore).Equal(item.fireAt) {
+			continue // todo was rescheduled; a fresh heap entry was already pushed
+		}
+
+		if err := s.notifier.Notify(ctx, t); err != nil {
+			s.logger.Error("failed to dispatch reminder", "todo_id", t.ID, "err", err)
+			continue
+		}
+		if !s.store.MarkReminderFired(t.OwnerID, t.ID, now) {
+			s.logger.Warn("failed to persist reminder watermark", "todo_id", t.ID)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeNotifier struct {
+	calls []Todo
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, t Todo) error {
+	f.calls = append(f.calls, t)
+	return nil
+}
+
+func newTestScheduler(store Storage, seed unscopedLister, notifier Notifier, broker *Broker) *Scheduler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewScheduler(store, seed, notifier, broker, logger)
+}
+
+// TestSchedulerPicksUpScheduleSetAfterStartup covers the bug where a
+// schedule set after Run's one-time startup seed (exactly what the PUT
+// handler and RecurringStorage.materializeNext do) never reached the
+// scheduler, because SetSchedule didn't publish a broker event.
+func TestSchedulerPicksUpScheduleSetAfterStartup(t *testing.T) {
+	fs := NewFileStore(filepath.Join(t.TempDir(), "todos.json"))
+	broker := NewBroker()
+	store := NewEventingStorage(fs, broker)
+	s := newTestScheduler(store, fs, &fakeNotifier{}, broker)
+
+	events, cancel := broker.SubscribeAll()
+	defer cancel()
+
+	todo := fs.Add("alice", "water plants")
+	due := time.Now().Add(time.Hour)
+	if _, ok := store.SetSchedule("alice", todo.ID, &due, "", 10*time.Minute); !ok {
+		t.Fatal("SetSchedule failed")
+	}
+
+	select {
+	case ev := <-events:
+		s.schedule(ev.Todo)
+	case <-time.After(time.Second):
+		t.Fatal("SetSchedule did not publish an event the scheduler could consume")
+	}
+
+	if s.heap.Len() != 1 {
+		t.Fatalf("expected SetSchedule's event to add a heap entry, heap has %d", s.heap.Len())
+	}
+}
+
+// TestSchedulerFireDueDoesNotDoubleFire covers the bug where the
+// already-fired guard compared ReminderFiredAt against DueAt (which a
+// reminder, by design, fires before), so it never actually suppressed a
+// duplicate. It also covers the heap accumulating a second entry for the
+// same todo when an unrelated edit republishes it before the first
+// reminder fires.
+func TestSchedulerFireDueDoesNotDoubleFire(t *testing.T) {
+	fs := NewFileStore(filepath.Join(t.TempDir(), "todos.json"))
+	broker := NewBroker()
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(fs, fs, notifier, broker)
+
+	todo := fs.Add("alice", "water plants")
+	due := time.Now().Add(-time.Minute)
+	updated, ok := fs.SetSchedule("alice", todo.ID, &due, "", time.Hour)
+	if !ok {
+		t.Fatal("SetSchedule failed")
+	}
+
+	// Two schedule() calls for the same unchanged due date, as would happen
+	// if the todo were republished (e.g. a title edit) before it fired.
+	s.schedule(updated)
+	s.schedule(updated)
+	if s.heap.Len() != 2 {
+		t.Fatalf("expected 2 heap entries pre-fire, got %d", s.heap.Len())
+	}
+
+	s.fireDue(context.Background())
+
+	if len(notifier.calls) != 1 {
+		t.Errorf("expected exactly one Notify call, got %d", len(notifier.calls))
+	}
+	if s.heap.Len() != 0 {
+		t.Errorf("expected fireDue to drain the stale duplicate entry too, heap has %d left", s.heap.Len())
+	}
+
+	got, ok := fs.Get("alice", todo.ID)
+	if !ok || got.ReminderFiredAt == nil {
+		t.Fatal("expected the reminder watermark to be persisted")
+	}
+
+	// A todo whose reminder already fired must not be rescheduled.
+	s.schedule(got)
+	if s.heap.Len() != 0 {
+		t.Error("schedule should not re-add a todo whose reminder already fired")
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Storage is the persistence boundary for todos. FileStore and SQLiteStore
+// both implement it so the HTTP handlers never depend on a concrete backend.
+type Storage interface {
+	All(ownerID string) []Todo
+	Add(ownerID, title string) Todo
+	Get(ownerID string, id int) (Todo, bool)
+	Update(ownerID string, id int, title *string, completed *bool) (Todo, bool)
+	Delete(ownerID string, id int) bool
+
+	// Query lists ownerID's todos matching filter, along with the total
+	// count before limit/offset were applied (for pagination headers).
+	Query(ownerID string, filter TodoFilter) ([]Todo, int)
+
+	// BatchAdd, BatchUpdate and BatchDelete each take their backend's lock
+	// (or transaction) once for the whole batch rather than once per item.
+	BatchAdd(ownerID string, titles []string) []Todo
+	BatchUpdate(ownerID string, ids []int, title *string, completed *bool) []BatchResult
+	BatchDelete(ownerID string, ids []int) []BatchResult
+
+	// SetSchedule sets the due date, recurrence rule and notification lead
+	// time on an existing todo, resetting its reminder watermark.
+	SetSchedule(ownerID string, id int, dueAt *time.Time, recurrence string, notifyBefore time.Duration) (Todo, bool)
+
+	// MarkReminderFired records that a reminder has been dispatched for the
+	// todo's current DueAt, so the scheduler doesn't fire it again after a
+	// restart.
+	MarkReminderFired(ownerID string, id int, at time.Time) bool
+
+	// Upcoming returns ownerID's todos due within the next `within` of now.
+	Upcoming(ownerID string, within time.Duration) []Todo
+}
+
+// TodoFilter narrows a Query call. A nil/zero field means "don't filter on
+// this"; Limit <= 0 means "no limit".
+type TodoFilter struct {
+	Completed *bool
+	Search    string
+	Sort      string // "created_at" or "title", optionally prefixed with "-" for descending
+	Limit     int
+	Offset    int
+}
+
+// BatchResult reports the outcome of one item in a batch update or delete:
+// either the resulting Todo or an error message, never both.
+type BatchResult struct {
+	ID    int    `json:"id"`
+	Todo  *Todo  `json:"todo,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// openStorage builds the Storage backend named by dsn, e.g. "file://todos.json"
+// or "sqlite:///data/todos.db". It's the single place that knows how to turn
+// a DSN string into a concrete backend.
+func openStorage(dsn string) (Storage, error) {
+	scheme, path, err := splitDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case "file":
+		return NewFileStore(path), nil
+	case "sqlite":
+		return NewSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("unknown storage scheme %q", scheme)
+	}
+}
+
+// splitDSN splits a "scheme://path" DSN into its scheme and path, e.g.
+// "file://todos.json" -> ("file", "todos.json") and
+// "sqlite:///data/todos.db" -> ("sqlite", "/data/todos.db").
+func splitDSN(dsn string) (scheme, path string, err error) {
+	scheme, path, ok := strings.Cut(dsn, "://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid storage DSN %q: want scheme://path", dsn)
+	}
+	return scheme, path, nil
+}
+
+// unscopedLister is implemented by every Storage backend to support
+// convertStorage, which must migrate todos across all owners at once rather
+// than one owner's view at a time.
+type unscopedLister interface {
+	allUnscoped() []Todo
+}
+
+// runConvert opens fromDSN and toDSN and copies every todo between them,
+// for the --convert-from/--convert-to CLI flags.
+func runConvert(fromDSN, toDSN string) error {
+	src, err := openStorage(fromDSN)
+	if err != nil {
+		return fmt.Errorf("open source: %w", err)
+	}
+	dst, err := openStorage(toDSN)
+	if err != nil {
+		return fmt.Errorf("open destination: %w", err)
+	}
+	n, err := convertStorage(src, dst)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("converted %d todos from %s to %s\n", n, fromDSN, toDSN)
+	return nil
+}
+
+// convertStorage copies every todo from src into dst, used by the
+// --convert-from/--convert-to flags to migrate between backends.
+func convertStorage(src, dst Storage) (int, error) {
+	lister, ok := src.(unscopedLister)
+	if !ok {
+		return 0, fmt.Errorf("convert: source backend does not support full export")
+	}
+	n := 0
+	for _, t := range lister.allUnscoped() {
+		created := dst.Add(t.OwnerID, t.Title)
+		completed := t.Completed
+		if _, ok := dst.Update(t.OwnerID, created.ID, nil, &completed); !ok {
+			return n, fmt.Errorf("convert: failed to write todo %d for owner %q", t.ID, t.OwnerID)
+		}
+		if t.DueAt != nil || t.Recurrence != "" || t.NotifyBefore != 0 {
+			if _, ok := dst.SetSchedule(t.OwnerID, created.ID, t.DueAt, t.Recurrence, t.NotifyBefore); !ok {
+				return n, fmt.Errorf("convert: failed to write schedule for todo %d for owner %q", t.ID, t.OwnerID)
+			}
+		}
+		if t.ReminderFiredAt != nil {
+			if !dst.MarkReminderFired(t.OwnerID, created.ID, *t.ReminderFiredAt) {
+				return n, fmt.Errorf("convert: failed to write reminder watermark for todo %d for owner %q", t.ID, t.OwnerID)
+			}
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import "time"
+
+// EventingStorage wraps a Storage backend and publishes a TodoEvent to
+// broker after every successful mutation, so handlers never have to
+// remember to publish themselves.
+type EventingStorage struct {
+	Storage
+	broker *Broker
+}
+
+func NewEventingStorage(next Storage, broker *Broker) *EventingStorage {
+	return &EventingStorage{Storage: next, broker: broker}
+}
+
+func (s *EventingStorage) Add(ownerID, title string) Todo {
+	t := s.Storage.Add(ownerID, title)
+	s.broker.Publish(TodoEvent{Kind: EventCreated, Todo: t})
+	return t
+}
+
+func (s *EventingStorage) Update(ownerID string, id int, title *string, completed *bool) (Todo, bool) {
+	t, ok := s.Storage.Update(ownerID, id, title, completed)
+	if ok {
+		s.broker.Publish(TodoEvent{Kind: EventUpdated, Todo: t})
+	}
+	return t, ok
+}
+
+func (s *EventingStorage) SetSchedule(ownerID string, id int, dueAt *time.Time, recurrence string, notifyBefore time.Duration) (Todo, bool) {
+	t, ok := s.Storage.SetSchedule(ownerID, id, dueAt, recurrence, notifyBefore)
+	if ok {
+		s.broker.Publish(TodoEvent{Kind: EventUpdated, Todo: t})
+	}
+	return t, ok
+}
+
+func (s *EventingStorage) MarkReminderFired(ownerID string, id int, at time.Time) bool {
+	ok := s.Storage.MarkReminderFired(ownerID, id, at)
+	if ok {
+		if t, found := s.Storage.Get(ownerID, id); found {
+			s.broker.Publish(TodoEvent{Kind: EventUpdated, Todo: t})
+		}
+	}
+	return ok
+}
+
+func (s *EventingStorage) Delete(ownerID string, id int) bool {
+	t, existed := s.Storage.Get(ownerID, id)
+	ok := s.Storage.Delete(ownerID, id)
+	if ok {
+		if !existed {
+			t = Todo{ID: id, OwnerID: ownerID}
+		}
+		s.broker.Publish(TodoEvent{Kind: EventDeleted, Todo: t})
+	}
+	return ok
+}
+
+func (s *EventingStorage) BatchAdd(ownerID string, titles []string) []Todo {
+	created := s.Storage.BatchAdd(ownerID, titles)
+	for _, t := range created {
+		s.broker.Publish(TodoEvent{Kind: EventCreated, Todo: t})
+	}
+	return created
+}
+
+func (s *EventingStorage) BatchUpdate(ownerID string, ids []int, title *string, completed *bool) []BatchResult {
+	results := s.Storage.BatchUpdate(ownerID, ids, title, completed)
+	for _, r := range results {
+		if r.Todo != nil {
+			s.broker.Publish(TodoEvent{Kind: EventUpdated, Todo: *r.Todo})
+		}
+	}
+	return results
+}
+
+func (s *EventingStorage) BatchDelete(ownerID string, ids []int) []BatchResult {
+	before := make(map[int]Todo, len(ids))
+	for _, id := range ids {
+		if t, ok := s.Storage.Get(ownerID, id); ok {
+			before[id] = t
+		}
+	}
+	results := s.Storage.BatchDelete(ownerID, ids)
+	for _, r := range results {
+		if r.Error == "" {
+			t := before[r.ID]
+			s.broker.Publish(TodoEvent{Kind: EventDeleted, Todo: t})
+		}
+	}
+	return results
+}
@@ -0,0 +1,50 @@
+package main
+
+import "log/slog"
+
+// RecurringStorage wraps a Storage backend and, whenever an Update marks a
+// recurring todo completed, materializes the next occurrence as a new todo.
+// The completed instance itself is left alone, so its history stays intact.
+type RecurringStorage struct {
+	Storage
+	logger *slog.Logger
+}
+
+func NewRecurringStorage(next Storage, logger *slog.Logger) *RecurringStorage {
+	return &RecurringStorage{Storage: next, logger: logger}
+}
+
+func (s *RecurringStorage) Update(ownerID string, id int, title *string, completed *bool) (Todo, bool) {
+	t, ok := s.Storage.Update(ownerID, id, title, completed)
+	if ok && completed != nil && *completed {
+		s.materializeNext(t)
+	}
+	return t, ok
+}
+
+func (s *RecurringStorage) BatchUpdate(ownerID string, ids []int, title *string, completed *bool) []BatchResult {
+	results := s.Storage.BatchUpdate(ownerID, ids, title, completed)
+	if completed != nil && *completed {
+		for _, r := range results {
+			if r.Todo != nil {
+				s.materializeNext(*r.Todo)
+			}
+		}
+	}
+	return results
+}
+
+func (s *RecurringStorage) materializeNext(t Todo) {
+	if t.Recurrence == "" || t.DueAt == nil {
+		return
+	}
+	next, err := nextOccurrence(*t.DueAt, t.Recurrence)
+	if err != nil {
+		s.logger.Warn("could not materialize recurring todo", "todo_id", t.ID, "recurrence", t.Recurrence, "err", err)
+		return
+	}
+	created := s.Storage.Add(t.OwnerID, t.Title)
+	if _, ok := s.Storage.SetSchedule(t.OwnerID, created.ID, &next, t.Recurrence, t.NotifyBefore); !ok {
+		s.logger.Warn("could not schedule materialized recurring todo", "todo_id", created.ID)
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore is the original Storage implementation: it keeps every todo in
+// memory and rewrites the whole JSON file on each mutation. Simple and fine
+// for small todo lists, but save() does not scale to large ones.
+type FileStore struct {
+	mu       sync.Mutex
+	todos    []Todo
+	nextID   int
+	filepath string
+}
+
+func NewFileStore(filepath string) *FileStore {
+	s := &FileStore{filepath: filepath, nextID: 1}
+	s.load()
+	return s
+}
+
+func (s *FileStore) load() {
+	data, err := os.ReadFile(s.filepath)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &s.todos); err != nil {
+		return
+	}
+	for _, t := range s.todos {
+		if t.ID >= s.nextID {
+			s.nextID = t.ID + 1
+		}
+	}
+}
+
+func (s *FileStore) save() error {
+	data, err := json.MarshalIndent(s.todos, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.filepath, data, 0644)
+}
+
+// All returns every todo owned by ownerID, in storage order.
+func (s *FileStore) All(ownerID string) []Todo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Todo, 0, len(s.todos))
+	for _, t := range s.todos {
+		if t.OwnerID == ownerID {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func (s *FileStore) allUnscoped() []Todo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Todo, len(s.todos))
+	copy(result, s.todos)
+	return result
+}
+
+func (s *FileStore) Add(ownerID, title string) Todo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := Todo{
+		ID:        s.nextID,
+		OwnerID:   ownerID,
+		Title:     title,
+		Completed: false,
+		CreatedAt: time.Now(),
+	}
+	s.nextID++
+	s.todos = append(s.todos, t)
+	s.save()
+	return t
+}
+
+func (s *FileStore) Get(ownerID string, id int) (Todo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.todos {
+		if t.ID == id && t.OwnerID == ownerID {
+			return t, true
+		}
+	}
+	return Todo{}, false
+}
+
+// Update applies the given fields to the todo with id, but only if it is
+// owned by ownerID; otherwise it reports not found so callers can't probe
+// for the existence of other users' todos.
+func (s *FileStore) Update(ownerID string, id int, title *string, completed *bool) (Todo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.todos {
+		if t.ID == id && t.OwnerID == ownerID {
+			if title != nil {
+				s.todos[i].Title = *title
+			}
+			if completed != nil {
+				s.todos[i].Completed = *completed
+			}
+			s.save()
+			return s.todos[i], true
+		}
+	}
+	return Todo{}, false
+}
+
+func (s *FileStore) Delete(ownerID string, id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.todos {
+		if t.ID == id && t.OwnerID == ownerID {
+			s.todos = append(s.todos[:i], s.todos[i+1:]...)
+			s.save()
+			return true
+		}
+	}
+	return false
+}
+
+// Query filters and sorts ownerID's todos in memory, then applies
+// limit/offset. It returns the page along with the total match count so
+// callers can paginate.
+func (s *FileStore) Query(ownerID string, filter TodoFilter) ([]Todo, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := make([]Todo, 0, len(s.todos))
+	for _, t := range s.todos {
+		if t.OwnerID != ownerID {
+			continue
+		}
+		if filter.Completed != nil && t.Completed != *filter.Completed {
+			continue
+		}
+		if filter.Search != "" && !strings.Contains(strings.ToLower(t.Title), strings.ToLower(filter.Search)) {
+			continue
+		}
+		matched = append(matched, t)
+	}
+
+	sortTodos(matched, filter.Sort)
+
+	total := len(matched)
+	if filter.Offset > 0 {
+		if filter.Offset >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[filter.Offset:]
+		}
+	}
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total
+}
+
+// sortTodos orders todos in place by "created_at" or "title", descending
+// when field is prefixed with "-". An unrecognized field leaves the order
+// unchanged (storage order, i.e. insertion order).
+func sortTodos(todos []Todo, field string) {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var less func(a, b Todo) bool
+	switch field {
+	case "title":
+		less = func(a, b Todo) bool { return a.Title < b.Title }
+	case "created_at":
+		less = func(a, b Todo) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	default:
+		return
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		if desc {
+			return less(todos[j], todos[i])
+		}
+		return less(todos[i], todos[j])
+	})
+}
+
+// BatchAdd creates every title as a new todo under a single lock acquisition.
+func (s *FileStore) BatchAdd(ownerID string, titles []string) []Todo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	created := make([]Todo, 0, len(titles))
+	for _, title := range titles {
+		t := Todo{
+			ID:        s.nextID,
+			OwnerID:   ownerID,
+			Title:     title,
+			Completed: false,
+			CreatedAt: time.Now(),
+		}
+		s.nextID++
+		s.todos = append(s.todos, t)
+		created = append(created, t)
+	}
+	s.save()
+	return created
+}
+
+func (s *FileStore) BatchUpdate(ownerID string, ids []int, title *string, completed *bool) []BatchResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := make([]BatchResult, 0, len(ids))
+	for _, id := range ids {
+		found := false
+		for i, t := range s.todos {
+			if t.ID == id && t.OwnerID == ownerID {
+				if title != nil {
+					s.todos[i].Title = *title
+				}
+				if completed != nil {
+					s.todos[i].Completed = *completed
+				}
+				updated := s.todos[i]
+				results = append(results, BatchResult{ID: id, Todo: &updated})
+				found = true
+				break
+			}
+		}
+		if !found {
+			results = append(results, BatchResult{ID: id, Error: "not found"})
+		}
+	}
+	s.save()
+	return results
+}
+
+func (s *FileStore) BatchDelete(ownerID string, ids []int) []BatchResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := make([]BatchResult, 0, len(ids))
+	for _, id := range ids {
+		found := false
+		for i, t := range s.todos {
+			if t.ID == id && t.OwnerID == ownerID {
+				s.todos = append(s.todos[:i], s.todos[i+1:]...)
+				results = append(results, BatchResult{ID: id})
+				found = true
+				break
+			}
+		}
+		if !found {
+			results = append(results, BatchResult{ID: id, Error: "not found"})
+		}
+	}
+	s.save()
+	return results
+}
+
+// SetSchedule sets the due date, recurrence rule and notification lead time
+// on a todo, clearing any previous reminder watermark so a new reminder can
+// fire for the new DueAt.
+func (s *FileStore) SetSchedule(ownerID string, id int, dueAt *time.Time, recurrence string, notifyBefore time.Duration) (Todo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.todos {
+		if t.ID == id && t.OwnerID == ownerID {
+			s.todos[i].DueAt = dueAt
+			s.todos[i].Recurrence = recurrence
+			s.todos[i].NotifyBefore = notifyBefore
+			s.todos[i].ReminderFiredAt = nil
+			s.save()
+			return s.todos[i], true
+		}
+	}
+	return Todo{}, false
+}
+
+func (s *FileStore) MarkReminderFired(ownerID string, id int, at time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.todos {
+		if t.ID == id && t.OwnerID == ownerID {
+			firedAt := at
+			s.todos[i].ReminderFiredAt = &firedAt
+			s.save()
+			return true
+		}
+	}
+	return false
+}
+
+// Upcoming returns ownerID's incomplete todos due between now and
+// now+within.
+func (s *FileStore) Upcoming(ownerID string, within time.Duration) []Todo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	deadline := now.Add(within)
+	var result []Todo
+	for _, t := range s.todos {
+		if t.OwnerID != ownerID || t.Completed || t.DueAt == nil {
+			continue
+		}
+		if t.DueAt.Before(now) || t.DueAt.After(deadline) {
+			continue
+		}
+		result = append(result, t)
+	}
+	sortTodos(result, "")
+	return result
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestFileStore(t *testing.T) *FileStore {
+	t.Helper()
+	return NewFileStore(filepath.Join(t.TempDir(), "todos.json"))
+}
+
+func TestFileStoreOwnershipScoping(t *testing.T) {
+	s := newTestFileStore(t)
+	alice := s.Add("alice", "alice's todo")
+	s.Add("bob", "bob's todo")
+
+	if _, ok := s.Get("bob", alice.ID); ok {
+		t.Error("bob should not be able to Get alice's todo by id")
+	}
+	if _, ok := s.Update("bob", alice.ID, nil, boolPtr(true)); ok {
+		t.Error("bob should not be able to Update alice's todo")
+	}
+	if s.Delete("bob", alice.ID) {
+		t.Error("bob should not be able to Delete alice's todo")
+	}
+
+	aliceTodos := s.All("alice")
+	if len(aliceTodos) != 1 || aliceTodos[0].ID != alice.ID {
+		t.Errorf("All(alice) = %+v, want only alice's todo", aliceTodos)
+	}
+}
+
+func TestFileStoreBatchUpdatePartialFailure(t *testing.T) {
+	s := newTestFileStore(t)
+	t1 := s.Add("alice", "first")
+	t2 := s.Add("alice", "second")
+	bobsTodo := s.Add("bob", "not alice's")
+
+	completed := true
+	results := s.BatchUpdate("alice", []int{t1.ID, t2.ID, bobsTodo.ID, 9999}, nil, &completed)
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+
+	for _, r := range results[:2] {
+		if r.Todo == nil || !r.Todo.Completed {
+			t.Errorf("expected todo %d to be completed, got %+v", r.ID, r)
+		}
+	}
+	for _, r := range results[2:] {
+		if r.Error == "" {
+			t.Errorf("expected an error for id %d (not alice's or nonexistent), got %+v", r.ID, r)
+		}
+	}
+
+	// bob's todo must be untouched by alice's batch.
+	got, ok := s.Get("bob", bobsTodo.ID)
+	if !ok || got.Completed {
+		t.Errorf("bob's todo should be unaffected by alice's batch update, got %+v", got)
+	}
+}
+
+func TestFileStoreBatchDeletePartialFailure(t *testing.T) {
+	s := newTestFileStore(t)
+	t1 := s.Add("alice", "first")
+	bobsTodo := s.Add("bob", "not alice's")
+
+	results := s.BatchDelete("alice", []int{t1.ID, bobsTodo.ID, 9999})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Error != "" {
+		t.Errorf("expected alice's own todo to delete cleanly, got %+v", results[0])
+	}
+	if results[1].Error == "" || results[2].Error == "" {
+		t.Errorf("expected errors for bob's todo and a nonexistent id, got %+v", results[1:])
+	}
+
+	if _, ok := s.Get("alice", t1.ID); ok {
+		t.Error("alice's todo should have been deleted")
+	}
+	if _, ok := s.Get("bob", bobsTodo.ID); !ok {
+		t.Error("bob's todo should still exist")
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS todos (
+	id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	owner_id          TEXT NOT NULL,
+	title             TEXT NOT NULL,
+	completed         INTEGER NOT NULL DEFAULT 0,
+	created_at        DATETIME NOT NULL,
+	due_at            TEXT,
+	recurrence        TEXT NOT NULL DEFAULT '',
+	notify_before_ns  INTEGER NOT NULL DEFAULT 0,
+	reminder_fired_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_todos_owner_id ON todos(owner_id);
+`
+
+const todoColumns = "id, owner_id, title, completed, created_at, due_at, recurrence, notify_before_ns, reminder_fired_at"
+
+// sqliteTimeLayout is a fixed-width, always-UTC timestamp format for the
+// due_at/reminder_fired_at TEXT columns. Unlike time.RFC3339Nano (which
+// trims trailing zero fractional digits), every formatted value has the
+// same length, so the plain string comparisons and ORDER BY clauses below
+// agree with chronological order.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func parseSQLiteTime(s string) (time.Time, error) {
+	return time.Parse(sqliteTimeLayout, s)
+}
+
+// SQLiteStore is a Storage backend on top of database/sql, for todo lists
+// too large to comfortably rewrite as a whole file on every mutation.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (and auto-migrates) the SQLite database at path.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite store: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate sqlite store: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) All(ownerID string) []Todo {
+	rows, err := s.db.Query(`SELECT `+todoColumns+` FROM todos WHERE owner_id = ? ORDER BY id`, ownerID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	return scanTodos(rows)
+}
+
+func (s *SQLiteStore) allUnscoped() []Todo {
+	rows, err := s.db.Query(`SELECT ` + todoColumns + ` FROM todos ORDER BY id`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	return scanTodos(rows)
+}
+
+// scanTodoRow scans a single row of todoColumns, shared by both row- and
+// rows-returning queries.
+func scanTodoRow(row interface {
+	Scan(dest ...any) error
+}) (Todo, error) {
+	var t Todo
+	var dueAt, reminderFiredAt sql.NullString
+	var notifyBeforeNS int64
+	if err := row.Scan(&t.ID, &t.OwnerID, &t.Title, &t.Completed, &t.CreatedAt, &dueAt, &t.Recurrence, &notifyBeforeNS, &reminderFiredAt); err != nil {
+		return Todo{}, err
+	}
+	t.NotifyBefore = time.Duration(notifyBeforeNS)
+	if dueAt.Valid {
+		parsed, err := parseSQLiteTime(dueAt.String)
+		if err == nil {
+			t.DueAt = &parsed
+		}
+	}
+	if reminderFiredAt.Valid {
+		parsed, err := parseSQLiteTime(reminderFiredAt.String)
+		if err == nil {
+			t.ReminderFiredAt = &parsed
+		}
+	}
+	return t, nil
+}
+
+func scanTodos(rows *sql.Rows) []Todo {
+	var todos []Todo
+	for rows.Next() {
+		t, err := scanTodoRow(rows)
+		if err != nil {
+			continue
+		}
+		todos = append(todos, t)
+	}
+	return todos
+}
+
+func formatNullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatSQLiteTime(*t)
+}
+
+func (s *SQLiteStore) Add(ownerID, title string) Todo {
+	t := Todo{OwnerID: ownerID, Title: title, Completed: false, CreatedAt: time.Now()}
+	res, err := s.db.Exec(`INSERT INTO todos (owner_id, title, completed, created_at) VALUES (?, ?, ?, ?)`,
+		t.OwnerID, t.Title, t.Completed, t.CreatedAt)
+	if err != nil {
+		return Todo{}
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Todo{}
+	}
+	t.ID = int(id)
+	return t
+}
+
+func (s *SQLiteStore) Get(ownerID string, id int) (Todo, bool) {
+	row := s.db.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = ? AND owner_id = ?`, id, ownerID)
+	t, err := scanTodoRow(row)
+	if err != nil {
+		return Todo{}, false
+	}
+	return t, true
+}
+
+// Update runs the read-modify-write as a single transaction so concurrent
+// updates to the same row can't interleave.
+func (s *SQLiteStore) Update(ownerID string, id int, title *string, completed *bool) (Todo, bool) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Todo{}, false
+	}
+	defer tx.Rollback()
+
+	t, err := scanTodoRow(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = ? AND owner_id = ?`, id, ownerID))
+	if err != nil {
+		return Todo{}, false
+	}
+	if title != nil {
+		t.Title = *title
+	}
+	if completed != nil {
+		t.Completed = *completed
+	}
+	if _, err := tx.Exec(`UPDATE todos SET title = ?, completed = ? WHERE id = ? AND owner_id = ?`,
+		t.Title, t.Completed, t.ID, t.OwnerID); err != nil {
+		return Todo{}, false
+	}
+	if err := tx.Commit(); err != nil {
+		return Todo{}, false
+	}
+	return t, true
+}
+
+func (s *SQLiteStore) Delete(ownerID string, id int) bool {
+	res, err := s.db.Exec(`DELETE FROM todos WHERE id = ? AND owner_id = ?`, id, ownerID)
+	if err != nil {
+		return false
+	}
+	n, err := res.RowsAffected()
+	return err == nil && n > 0
+}
+
+// Query builds a WHERE/ORDER BY/LIMIT clause from filter and runs it as a
+// single query, plus a COUNT(*) for the pre-pagination total.
+func (s *SQLiteStore) Query(ownerID string, filter TodoFilter) ([]Todo, int) {
+	where := "owner_id = ?"
+	args := []any{ownerID}
+	if filter.Completed != nil {
+		where += " AND completed = ?"
+		args = append(args, *filter.Completed)
+	}
+	if filter.Search != "" {
+		where += " AND title LIKE ? ESCAPE '\\'"
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+	}
+
+	var total int
+	countRow := s.db.QueryRow(`SELECT COUNT(*) FROM todos WHERE `+where, args...)
+	if err := countRow.Scan(&total); err != nil {
+		return nil, 0
+	}
+
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE ` + where + orderByClause(filter.Sort)
+	queryArgs := args
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		queryArgs = append(queryArgs, filter.Limit, filter.Offset)
+	} else if filter.Offset > 0 {
+		query += " LIMIT -1 OFFSET ?"
+		queryArgs = append(queryArgs, filter.Offset)
+	}
+
+	rows, err := s.db.Query(query, queryArgs...)
+	if err != nil {
+		return nil, total
+	}
+	defer rows.Close()
+	return scanTodos(rows), total
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// orderByClause maps a Query sort field ("created_at" or "title", optionally
+// "-"-prefixed for descending) to a SQL ORDER BY clause.
+func orderByClause(field string) string {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+	column, ok := map[string]string{"title": "title", "created_at": "created_at"}[field]
+	if !ok {
+		return " ORDER BY id"
+	}
+	if desc {
+		return " ORDER BY " + column + " DESC"
+	}
+	return " ORDER BY " + column + " ASC"
+}
+
+// BatchAdd inserts every title inside a single transaction.
+func (s *SQLiteStore) BatchAdd(ownerID string, titles []string) []Todo {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil
+	}
+	defer tx.Rollback()
+
+	created := make([]Todo, 0, len(titles))
+	for _, title := range titles {
+		t := Todo{OwnerID: ownerID, Title: title, Completed: false, CreatedAt: time.Now()}
+		res, err := tx.Exec(`INSERT INTO todos (owner_id, title, completed, created_at) VALUES (?, ?, ?, ?)`,
+			t.OwnerID, t.Title, t.Completed, t.CreatedAt)
+		if err != nil {
+			return nil
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil
+		}
+		t.ID = int(id)
+		created = append(created, t)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil
+	}
+	return created
+}
+
+func (s *SQLiteStore) BatchUpdate(ownerID string, ids []int, title *string, completed *bool) []BatchResult {
+	tx, err := s.db.Begin()
+	if err != nil {
+		results := make([]BatchResult, len(ids))
+		for i, id := range ids {
+			results[i] = BatchResult{ID: id, Error: fmt.Sprintf("begin transaction: %v", err)}
+		}
+		return results
+	}
+	defer tx.Rollback()
+
+	results := make([]BatchResult, 0, len(ids))
+	for _, id := range ids {
+		t, err := scanTodoRow(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = ? AND owner_id = ?`, id, ownerID))
+		if err != nil {
+			results = append(results, BatchResult{ID: id, Error: "not found"})
+			continue
+		}
+		if title != nil {
+			t.Title = *title
+		}
+		if completed != nil {
+			t.Completed = *completed
+		}
+		if _, err := tx.Exec(`UPDATE todos SET title = ?, completed = ? WHERE id = ? AND owner_id = ?`,
+			t.Title, t.Completed, t.ID, t.OwnerID); err != nil {
+			results = append(results, BatchResult{ID: id, Error: err.Error()})
+			continue
+		}
+		updated := t
+		results = append(results, BatchResult{ID: id, Todo: &updated})
+	}
+	if err := tx.Commit(); err != nil {
+		for i := range results {
+			results[i] = BatchResult{ID: results[i].ID, Error: "transaction failed"}
+		}
+	}
+	return results
+}
+
+func (s *SQLiteStore) BatchDelete(ownerID string, ids []int) []BatchResult {
+	tx, err := s.db.Begin()
+	if err != nil {
+		results := make([]BatchResult, len(ids))
+		for i, id := range ids {
+			results[i] = BatchResult{ID: id, Error: fmt.Sprintf("begin transaction: %v", err)}
+		}
+		return results
+	}
+	defer tx.Rollback()
+
+	results := make([]BatchResult, 0, len(ids))
+	for _, id := range ids {
+		res, err := tx.Exec(`DELETE FROM todos WHERE id = ? AND owner_id = ?`, id, ownerID)
+		if err != nil {
+			results = append(results, BatchResult{ID: id, Error: err.Error()})
+			continue
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			results = append(results, BatchResult{ID: id, Error: "not found"})
+			continue
+		}
+		results = append(results, BatchResult{ID: id})
+	}
+	if err := tx.Commit(); err != nil {
+		for i := range results {
+			results[i] = BatchResult{ID: results[i].ID, Error: "transaction failed"}
+		}
+	}
+	return results
+}
+
+// SetSchedule sets the due date, recurrence rule and notification lead time
+// on a todo, clearing any previous reminder watermark.
+func (s *SQLiteStore) SetSchedule(ownerID string, id int, dueAt *time.Time, recurrence string, notifyBefore time.Duration) (Todo, bool) {
+	res, err := s.db.Exec(`UPDATE todos SET due_at = ?, recurrence = ?, notify_before_ns = ?, reminder_fired_at = NULL WHERE id = ? AND owner_id = ?`,
+		formatNullableTime(dueAt), recurrence, int64(notifyBefore), id, ownerID)
+	if err != nil {
+		return Todo{}, false
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return Todo{}, false
+	}
+	return s.Get(ownerID, id)
+}
+
+func (s *SQLiteStore) MarkReminderFired(ownerID string, id int, at time.Time) bool {
+	res, err := s.db.Exec(`UPDATE todos SET reminder_fired_at = ? WHERE id = ? AND owner_id = ?`,
+		formatSQLiteTime(at), id, ownerID)
+	if err != nil {
+		return false
+	}
+	n, err := res.RowsAffected()
+	return err == nil && n > 0
+}
+
+// Upcoming returns ownerID's incomplete todos due between now and
+// now+within.
+func (s *SQLiteStore) Upcoming(ownerID string, within time.Duration) []Todo {
+	now := time.Now()
+	deadline := now.Add(within)
+	rows, err := s.db.Query(`SELECT `+todoColumns+` FROM todos
+		WHERE owner_id = ? AND completed = 0 AND due_at IS NOT NULL AND due_at >= ? AND due_at <= ?
+		ORDER BY due_at`,
+		ownerID, formatSQLiteTime(now), formatSQLiteTime(deadline))
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	return scanTodos(rows)
+}
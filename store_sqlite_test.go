@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "todos.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteStoreUpcomingOrdersChronologically(t *testing.T) {
+	s := newTestSQLiteStore(t)
+	base := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	// Due times chosen so that naive lexicographic RFC3339Nano comparison
+	// (trailing-zero digits trimmed) would misorder them relative to the
+	// fixed-width format this store now uses.
+	due := []time.Time{
+		base.Add(2 * time.Second),
+		base,
+		base.Add(500 * time.Millisecond),
+	}
+	for i, d := range due {
+		todo := s.Add("alice", "todo")
+		if _, ok := s.SetSchedule("alice", todo.ID, &d, "", 0); !ok {
+			t.Fatalf("SetSchedule(%d): failed", i)
+		}
+	}
+
+	got := s.Upcoming("alice", 2*time.Hour)
+	if len(got) != 3 {
+		t.Fatalf("Upcoming returned %d todos, want 3", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].DueAt.Before(*got[i-1].DueAt) {
+			t.Errorf("Upcoming is not chronologically ordered: %v before %v", got[i].DueAt, got[i-1].DueAt)
+		}
+	}
+}
+
+func TestSQLiteStoreSetScheduleRoundTrip(t *testing.T) {
+	s := newTestSQLiteStore(t)
+	todo := s.Add("alice", "water plants")
+
+	due := time.Now().Add(24 * time.Hour)
+	updated, ok := s.SetSchedule("alice", todo.ID, &due, "FREQ=DAILY;INTERVAL=1", time.Hour)
+	if !ok {
+		t.Fatal("SetSchedule failed")
+	}
+	if updated.DueAt == nil || !updated.DueAt.Equal(due) {
+		t.Errorf("DueAt round-tripped to %v, want %v", updated.DueAt, due)
+	}
+	if updated.Recurrence != "FREQ=DAILY;INTERVAL=1" || updated.NotifyBefore != time.Hour {
+		t.Errorf("schedule round-tripped to %+v", updated)
+	}
+
+	fired := time.Now()
+	if !s.MarkReminderFired("alice", todo.ID, fired) {
+		t.Fatal("MarkReminderFired failed")
+	}
+	got, _ := s.Get("alice", todo.ID)
+	if got.ReminderFiredAt == nil || !got.ReminderFiredAt.Equal(fired) {
+		t.Errorf("ReminderFiredAt round-tripped to %v, want %v", got.ReminderFiredAt, fired)
+	}
+}